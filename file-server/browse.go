@@ -0,0 +1,130 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const browseHTML = `<!DOCTYPE html>
+<html>
+<head><title>Index of {{.Path}}</title></head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<ul>
+{{range .Entries}}<li><a href="{{.Path}}">{{.Name}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`
+
+// DefaultBrowseTemplate is the template used to render directory listings
+// when Options.BrowseTemplate is nil.
+var DefaultBrowseTemplate = template.Must(template.New("browse").Parse(browseHTML))
+
+// browseEntry is a single row of a directory listing.
+type browseEntry struct {
+	Name  string    `json:"name"`
+	Path  string    `json:"path"`
+	Size  int64     `json:"size"`
+	MTime time.Time `json:"mtime"`
+	IsDir bool      `json:"is_dir"`
+}
+
+// browseListing is the body rendered, as HTML or JSON, by serveBrowse.
+type browseListing struct {
+	Path    string        `json:"path"`
+	Entries []browseEntry `json:"entries"`
+}
+
+// serveBrowse renders a directory listing of the directory opened as f at
+// p, as JSON when the request asks for it via the Accept header or the
+// format query parameter, or as HTML otherwise. The config overlay file
+// itself is always excluded; remaining entries are filtered by
+// Options.HiddenGlobs and the hidden globs of the configuration overlays
+// merged from the root down to p, and ordered by the sort and order query
+// parameters.
+func (s *Server) serveBrowse(w http.ResponseWriter, r *http.Request, p string, f http.File) {
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		s.httpError(w, r, err)
+		return
+	}
+
+	o := s.overlayFor(p)
+
+	listing := browseListing{Path: p}
+	for _, e := range entries {
+		if e.Name() == s.configFilename() || s.browseHidden(e.Name()) || o.isHidden(e.Name()) {
+			continue
+		}
+		listing.Entries = append(listing.Entries, browseEntry{
+			Name:  e.Name(),
+			Path:  path.Join(s.root, p, e.Name()),
+			Size:  e.Size(),
+			MTime: e.ModTime(),
+			IsDir: e.IsDir(),
+		})
+	}
+
+	q := r.URL.Query()
+	sortBrowseEntries(listing.Entries, q.Get("sort"), q.Get("order"))
+
+	if q.Get("format") == "json" || acceptsJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listing)
+		return
+	}
+
+	t := s.BrowseTemplate
+	if t == nil {
+		t = DefaultBrowseTemplate
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	t.Execute(w, listing)
+}
+
+func (s *Server) browseHidden(name string) bool {
+	for _, g := range s.HiddenGlobs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsJSON(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		if strings.HasPrefix(strings.TrimSpace(part), "application/json") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortBrowseEntries(entries []browseEntry, by, order string) {
+	less := func(i, j int) bool {
+		switch by {
+		case "size":
+			return entries[i].Size < entries[j].Size
+		case "time":
+			return entries[i].MTime.Before(entries[j].MTime)
+		default:
+			return entries[i].Name < entries[j].Name
+		}
+	}
+	if order == "desc" {
+		asc := less
+		less = func(i, j int) bool { return asc(j, i) }
+	}
+	sort.SliceStable(entries, less)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServeBrowseHonorsConfigOverlayHidden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-browse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"visible.txt", "secret.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	overlay := "hidden:\n  - secret.txt\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, DefaultConfigFilename), []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New("", dir, &Options{Browse: true})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var listing browseListing
+	if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range listing.Entries {
+		if entry.Name == "secret.txt" {
+			t.Fatalf("secret.txt hidden by overlay still appears in listing: %+v", listing.Entries)
+		}
+	}
+
+	var sawVisible bool
+	for _, entry := range listing.Entries {
+		if entry.Name == "visible.txt" {
+			sawVisible = true
+		}
+	}
+	if !sawVisible {
+		t.Fatalf("visible.txt missing from listing: %+v", listing.Entries)
+	}
+}
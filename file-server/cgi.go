@@ -0,0 +1,91 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// resolveCGI walks the segments of p the way RFC 3875 PATH_INFO resolution
+// is usually implemented: for "/a/b/c/d" it stats "/a", "/a/b", "/a/b/c",
+// ... in order, and returns the first regular, executable match as script.
+// The walk stops at the first os.IsNotExist error, so the cost is bounded
+// by the depth of the first missing segment.
+func (s *Server) resolveCGI(p string) (script string, ok bool) {
+	if len(s.CGIExtensions) == 0 && s.CGIDir == "" {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	candidate := ""
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		candidate = path.Join(candidate, segment)
+
+		f, err := s.open("/" + candidate)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", false
+			}
+			continue
+		}
+		d, err := f.Stat()
+		f.Close()
+		if err != nil {
+			continue
+		}
+		if d.IsDir() {
+			continue
+		}
+		if s.isCGIScript("/"+candidate, d) {
+			return "/" + candidate, true
+		}
+	}
+	return "", false
+}
+
+func (s *Server) isCGIScript(p string, d os.FileInfo) bool {
+	if d.Mode()&0111 == 0 {
+		return false
+	}
+	if s.CGIDir != "" && (p == s.CGIDir || strings.HasPrefix(p, s.CGIDir+"/")) {
+		return true
+	}
+	ext := path.Ext(p)
+	for _, e := range s.CGIExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// serveCGI executes script, found at a path previously returned by
+// resolveCGI, as a standard RFC 3875 CGI program, setting SCRIPT_NAME,
+// PATH_INFO and QUERY_STRING from the request, piping the body to stdin,
+// and streaming the parsed response back to w.
+func (s *Server) serveCGI(w http.ResponseWriter, r *http.Request, script string) {
+	dir := s.dir
+	if s.AltDir != "" {
+		if fi, err := os.Stat(filepath.Join(s.AltDir, script)); err == nil && fi.Mode().IsRegular() {
+			dir = s.AltDir
+		}
+	}
+
+	h := &cgi.Handler{
+		Path: filepath.Join(dir, script),
+		Root: path.Join(s.root, script),
+		Dir:  filepath.Join(dir, path.Dir(script)),
+	}
+	h.ServeHTTP(w, r)
+}
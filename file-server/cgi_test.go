@@ -0,0 +1,88 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCGIRespectsConfigOverlayAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-cgi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := "#!/bin/sh\nprintf 'Content-Type: text/plain\\r\\n\\r\\nsecret data'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "secret.cgi"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	overlay := "auth:\n  type: basic\n  users:\n    admin: secret\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, DefaultConfigFilename), []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New("", dir, &Options{
+		CGIExtensions: []string{".cgi"},
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/secret.cgi", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d for unauthenticated CGI request", w.Code, http.StatusUnauthorized)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/secret.cgi", nil)
+	r.SetBasicAuth("admin", "secret")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d for authenticated CGI request", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "secret data" {
+		t.Fatalf("got body %q, want %q", got, "secret data")
+	}
+}
+
+func TestCGIDirDoesNotMatchSiblingDirectoryByPrefix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-cgi-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, sub := range []string{"cgi-bin", "cgi-binary"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	script := "#!/bin/sh\nprintf 'Content-Type: text/plain\\r\\n\\r\\nran as cgi'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "cgi-binary", "run.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New("", dir, &Options{
+		CGIDir: "/cgi-bin",
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/cgi-binary/run.sh", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if got := w.Body.String(); got == "ran as cgi" {
+		t.Fatalf("/cgi-binary/run.sh was executed as a CGI script under CGIDir %q", "/cgi-bin")
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import "errors"
+
+var (
+	errNotFound       = errors.New("not found")
+	errNotRegularFile = errors.New("not a regular file")
+)
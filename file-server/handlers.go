@@ -0,0 +1,28 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import "net/http"
+
+// Default handlers used by Server when the corresponding Options handler
+// is not set.
+var (
+	DefaultNotFoundHandler            = http.HandlerFunc(defaultNotFoundHandler)
+	DefaultForbiddenHandler           = http.HandlerFunc(defaultForbiddenHandler)
+	DefaultInternalServerErrorHandler = http.HandlerFunc(defaultInternalServerErrorHandler)
+)
+
+func defaultNotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+}
+
+func defaultForbiddenHandler(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+func defaultInternalServerErrorHandler(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import "io"
+
+// Hasher calculates a content hash that Server injects into file names for
+// long-term cache busting, and validates whether a string found in a file
+// name is such a hash.
+type Hasher interface {
+	Hash(r io.Reader) (string, error)
+	IsHash(s string) bool
+}
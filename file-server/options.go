@@ -0,0 +1,79 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// Options define parameters that configure the behavior of a Server.
+type Options struct {
+	// IndexPage is the filename that is served when a directory is
+	// requested, e.g. "index.html".
+	IndexPage string
+	// AltDir, if not empty, is checked for a file before dir, allowing an
+	// override directory to shadow individual files.
+	AltDir string
+	// Filenames, if set, is a precomputed list of all file paths served by
+	// the Server, used to avoid globbing the filesystem on every hashed
+	// path lookup.
+	Filenames []string
+	// Hasher, if set, enables cache-busting by injecting and validating
+	// content hashes in file names.
+	Hasher Hasher
+	// NoHashQueryStrings disables hash redirection for requests that carry
+	// a query string.
+	NoHashQueryStrings bool
+	// RedirectTrailingSlash enables redirects that normalize trailing
+	// slashes on directories and files.
+	RedirectTrailingSlash bool
+
+	// PrecompressedEncodings lists, in preference order, the
+	// Accept-Encoding tokens (e.g. "br", "gzip") that Server negotiates
+	// against precomputed sibling files (foo.js.br, foo.js.gz) found next
+	// to the requested file.
+	PrecompressedEncodings []string
+	// CompressOnTheFly enables gzip compression of the response when the
+	// request accepts it and no precomputed variant from
+	// PrecompressedEncodings is found.
+	CompressOnTheFly bool
+
+	// SearchIndexInterval, if positive, enables the search index built by
+	// StartSearchIndex and sets how often it is rebuilt.
+	SearchIndexInterval time.Duration
+	// SearchIgnore lists glob patterns, matched against file and directory
+	// names, that are excluded from the search index.
+	SearchIgnore []string
+
+	// CGIExtensions lists file extensions (e.g. ".cgi", ".py") that mark a
+	// regular, executable file as a CGI script.
+	CGIExtensions []string
+	// CGIDir, if not empty, marks every regular, executable file under it
+	// as a CGI script, regardless of extension.
+	CGIDir string
+
+	// ConfigFilename is the per-directory configuration overlay filename,
+	// defaulting to DefaultConfigFilename.
+	ConfigFilename string
+
+	// Browse enables directory listings for directories without an
+	// IndexPage, instead of the default 404 response.
+	Browse bool
+	// BrowseTemplate, if set, overrides DefaultBrowseTemplate for
+	// rendering HTML directory listings.
+	BrowseTemplate *template.Template
+	// HiddenGlobs lists glob patterns, matched against file and directory
+	// names, that are excluded from directory listings.
+	HiddenGlobs []string
+
+	// NotFoundHandler, ForbiddenHandler and InternalServerErrorHandler, if
+	// set, replace the corresponding Default*Handler.
+	NotFoundHandler            http.Handler
+	ForbiddenHandler           http.Handler
+	InternalServerErrorHandler http.Handler
+}
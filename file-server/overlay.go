@@ -0,0 +1,242 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultConfigFilename is the per-directory configuration overlay
+// filename used when Options.ConfigFilename is empty.
+const DefaultConfigFilename = ".httputils.yml"
+
+// Redirect is a single redirect rule configured in an overlay file.
+type Redirect struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	Code int    `yaml:"code"`
+}
+
+// Auth describes access control configured in an overlay file.
+type Auth struct {
+	Type  string            `yaml:"type"`
+	Users map[string]string `yaml:"users"`
+}
+
+// overlay is the parsed content of a single configuration file, merged
+// from the root directory down to the directory being served.
+type overlay struct {
+	CacheControl          string     `yaml:"cache_control"`
+	ContentSecurityPolicy string     `yaml:"content_security_policy"`
+	Redirects             []Redirect `yaml:"redirects"`
+	Auth                  *Auth      `yaml:"auth"`
+	Hidden                []string   `yaml:"hidden"`
+	NotFound              string     `yaml:"not_found"`
+}
+
+// merge returns o applied on top of base: o's scalar fields take
+// precedence when set, and its list fields are appended after base's, so
+// that a subdirectory can refine, rather than replace, its parent's
+// policy.
+func (o overlay) merge(base overlay) overlay {
+	m := base
+	if o.CacheControl != "" {
+		m.CacheControl = o.CacheControl
+	}
+	if o.ContentSecurityPolicy != "" {
+		m.ContentSecurityPolicy = o.ContentSecurityPolicy
+	}
+	if o.Auth != nil {
+		m.Auth = o.Auth
+	}
+	if o.NotFound != "" {
+		m.NotFound = o.NotFound
+	}
+	m.Redirects = append(append([]Redirect{}, m.Redirects...), o.Redirects...)
+	m.Hidden = append(append([]string{}, m.Hidden...), o.Hidden...)
+	return m
+}
+
+func (o overlay) matchRedirect(p string) (to string, code int, ok bool) {
+	for _, rr := range o.Redirects {
+		if rr.From == p {
+			code := rr.Code
+			if code == 0 {
+				code = http.StatusFound
+			}
+			return rr.To, code, true
+		}
+	}
+	return "", 0, false
+}
+
+func (o overlay) isHidden(name string) bool {
+	for _, g := range o.Hidden {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize enforces o.Auth, writing a 401 response and returning false
+// when the request fails authentication. Only the "basic" type is
+// enforced; an Auth of any other type lets every request through.
+func (o overlay) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if o.Auth == nil || o.Auth.Type != "basic" {
+		return true
+	}
+	if user, pass, ok := r.BasicAuth(); ok {
+		if want, exists := o.Auth.Users[user]; exists && want == pass {
+			return true
+		}
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="restricted"`)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	return false
+}
+
+// overlayCacheEntry is a single cached, parsed overlay file.
+type overlayCacheEntry struct {
+	dir   string
+	mtime int64
+	o     overlay
+}
+
+// overlayCache is a small LRU cache of parsed configuration overlays,
+// keyed by directory and invalidated when the overlay file's modification
+// time changes.
+type overlayCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newOverlayCache(size int) *overlayCache {
+	if size <= 0 {
+		size = 128
+	}
+	return &overlayCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *overlayCache) get(dir string, mtime int64) (overlay, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[dir]
+	if !ok {
+		return overlay{}, false
+	}
+	entry := e.Value.(*overlayCacheEntry)
+	if entry.mtime != mtime {
+		return overlay{}, false
+	}
+	c.order.MoveToFront(e)
+	return entry.o, true
+}
+
+func (c *overlayCache) set(dir string, mtime int64, o overlay) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[dir]; ok {
+		e.Value = &overlayCacheEntry{dir: dir, mtime: mtime, o: o}
+		c.order.MoveToFront(e)
+		return
+	}
+	e := c.order.PushFront(&overlayCacheEntry{dir: dir, mtime: mtime, o: o})
+	c.entries[dir] = e
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*overlayCacheEntry).dir)
+	}
+}
+
+func (s *Server) configFilename() string {
+	if s.ConfigFilename != "" {
+		return s.ConfigFilename
+	}
+	return DefaultConfigFilename
+}
+
+// overlayFor reads and merges the configuration overlays found from the
+// root directory down to dir, a "/"-separated URL directory path, so that
+// a subdirectory's overlay refines rather than replaces its parent's.
+func (s *Server) overlayFor(dir string) overlay {
+	var merged overlay
+	cur := "/"
+	merged = merged.merge(s.readOverlay(cur))
+	for _, segment := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		cur = path.Join(cur, segment)
+		merged = s.readOverlay(cur).merge(merged)
+	}
+	return merged
+}
+
+func (s *Server) readOverlay(dir string) overlay {
+	name := path.Join(dir, s.configFilename())
+
+	f, err := s.open(name)
+	if err != nil {
+		return overlay{}
+	}
+	defer f.Close()
+
+	d, err := f.Stat()
+	if err != nil || d.IsDir() {
+		return overlay{}
+	}
+
+	if o, ok := s.overlayCache.get(dir, d.ModTime().UnixNano()); ok {
+		return o
+	}
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return overlay{}
+	}
+	var o overlay
+	if err := yaml.Unmarshal(b, &o); err != nil {
+		return overlay{}
+	}
+
+	s.overlayCache.set(dir, d.ModTime().UnixNano(), o)
+	return o
+}
+
+// httpErrorOverlay writes the configured NotFound override of o, if any,
+// otherwise delegates to s.httpError.
+func (s Server) httpErrorOverlay(w http.ResponseWriter, r *http.Request, err error, o overlay) {
+	if (os.IsNotExist(err) || err == errNotFound) && o.NotFound != "" {
+		if f, ferr := s.open(o.NotFound); ferr == nil {
+			defer f.Close()
+			if d, derr := f.Stat(); derr == nil && !d.IsDir() {
+				w.WriteHeader(http.StatusNotFound)
+				http.ServeContent(w, r, d.Name(), d.ModTime(), f)
+				return
+			}
+		}
+	}
+	s.httpError(w, r, err)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-overlay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		DefaultConfigFilename: "" +
+			"cache_control: \"public, max-age=60\"\n" +
+			"content_security_policy: \"default-src 'self'\"\n" +
+			"redirects:\n" +
+			"  - from: /old.txt\n" +
+			"    to: /new.txt\n" +
+			"    code: 301\n" +
+			"not_found: /notfound.html\n",
+		"new.txt":       "new",
+		"notfound.html": "custom not found",
+		"sub/" + DefaultConfigFilename: "" +
+			"cache_control: \"no-store\"\n" +
+			"hidden:\n" +
+			"  - secret.txt\n",
+		"sub/a.txt":      "visible",
+		"sub/secret.txt": "secret",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := New("", dir, &Options{Browse: true})
+
+	t.Run("merge precedence", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/sub/a.txt", nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("got Cache-Control %q, want child override %q", got, "no-store")
+		}
+		if got := w.Header().Get("Content-Security-Policy"); got != "default-src 'self'" {
+			t.Errorf("got Content-Security-Policy %q, want inherited %q", got, "default-src 'self'")
+		}
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/old.txt", nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusMovedPermanently)
+		}
+		if got := w.Header().Get("Location"); got != "/new.txt" {
+			t.Errorf("got Location %q, want /new.txt", got)
+		}
+	})
+
+	t.Run("not found override inherited by subdirectory", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/sub/missing.txt", nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+		}
+		if got := w.Body.String(); got != "custom not found" {
+			t.Errorf("got body %q, want %q", got, "custom not found")
+		}
+	})
+
+	t.Run("config file itself is never served", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/"+DefaultConfigFilename, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("got status %d for direct request to config file, want %d", w.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("browse hides secret and config file", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/sub/", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+		}
+		var listing browseListing
+		if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil {
+			t.Fatal(err)
+		}
+		names := map[string]bool{}
+		for _, e := range listing.Entries {
+			names[e.Name] = true
+		}
+		if names["secret.txt"] {
+			t.Errorf("secret.txt hidden by overlay still listed: %+v", listing.Entries)
+		}
+		if names[DefaultConfigFilename] {
+			t.Errorf("config file still listed: %+v", listing.Entries)
+		}
+		if !names["a.txt"] {
+			t.Errorf("a.txt missing from listing: %+v", listing.Entries)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// precompressedExtensions maps an encoding token, as found in the
+// Accept-Encoding header, to the filename suffix of its precomputed sibling
+// file.
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// serveContent writes f, the file at p with info d, to w. If Options
+// specifies PrecompressedEncodings and the request accepts one of them, a
+// precomputed sibling file is served instead, with the original file's
+// modification time preserved for Last-Modified and Range handling. If no
+// precomputed variant is available and CompressOnTheFly is set, the
+// response is gzip-compressed on the fly instead.
+func (s *Server) serveContent(w http.ResponseWriter, r *http.Request, p string, d os.FileInfo, f http.File) {
+	if enc, cf := s.precompressedVariant(r, p); cf != nil {
+		defer cf.Close()
+		s.setCompressedHeaders(w, p, enc)
+		http.ServeContent(w, r, "", d.ModTime(), cf)
+		return
+	}
+
+	if s.CompressOnTheFly && acceptsEncoding(r.Header.Get("Accept-Encoding"), "gzip") {
+		s.setCompressedHeaders(w, p, "gzip")
+		w.WriteHeader(http.StatusOK)
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		io.Copy(gz, f)
+		return
+	}
+
+	http.ServeContent(w, r, d.Name(), d.ModTime(), f)
+}
+
+func (s *Server) setCompressedHeaders(w http.ResponseWriter, p, enc string) {
+	w.Header().Set("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Encoding", enc)
+	if ct := mime.TypeByExtension(path.Ext(p)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	if s.Hasher != nil {
+		if h, _, err := s.hash(p); err == nil && h != "" {
+			w.Header().Set("ETag", strconv.Quote(h))
+		}
+	}
+}
+
+// precompressedVariant looks up, in the order given by
+// Options.PrecompressedEncodings, a sibling file of p (e.g. p+".br",
+// p+".gz") that the request accepts. It returns a nil file if none of the
+// configured encodings is both accepted and available.
+func (s *Server) precompressedVariant(r *http.Request, p string) (enc string, f http.File) {
+	if len(s.PrecompressedEncodings) == 0 {
+		return "", nil
+	}
+	accept := r.Header.Get("Accept-Encoding")
+	for _, e := range s.PrecompressedEncodings {
+		ext, ok := precompressedExtensions[e]
+		if !ok || !acceptsEncoding(accept, e) {
+			continue
+		}
+		cf, err := s.open(p + ext)
+		if err != nil {
+			continue
+		}
+		cd, err := cf.Stat()
+		if err != nil || !cd.Mode().IsRegular() {
+			cf.Close()
+			continue
+		}
+		return e, cf
+	}
+	return "", nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header value h lists
+// token with a non-zero quality value.
+func acceptsEncoding(h, token string) bool {
+	for _, part := range strings.Split(h, ",") {
+		part = strings.TrimSpace(part)
+		name := part
+		q := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			name = strings.TrimSpace(part[:i])
+			if qv := strings.TrimSpace(part[i+1:]); strings.HasPrefix(qv, "q=") {
+				if v, err := strconv.ParseFloat(qv[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if name == token {
+			return q > 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeHasher is a minimal Hasher used to exercise the ETag path without
+// pulling in a real hashing implementation. Its hash is the content length
+// in hex, and IsHash accepts any hex string so that hashed paths resolve.
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(r io.Reader) (string, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", len(b)), nil
+}
+
+func (fakeHasher) IsHash(s string) bool {
+	_, err := strconv.ParseUint(s, 16, 64)
+	return err == nil
+}
+
+func TestServeContentCompressOnTheFlyWithoutHasher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-precompressed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New("", dir, &Options{
+		CompressOnTheFly: true,
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("got Content-Encoding %q, want gzip", enc)
+	}
+}
+
+func TestServeContentPrecompressedVariant(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-precompressed-variant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	original := []byte("console.log('hello world');")
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo.js"), original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	if _, err := gz.Write(original); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	compressed := gzBuf.Bytes()
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo.js.gz"), compressed, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New("", dir, &Options{
+		PrecompressedEncodings: []string{"gzip"},
+		Hasher:                 fakeHasher{},
+	})
+
+	// With a Hasher set, the unhashed path is redirected, so request the
+	// hashed one.
+	r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/foo.%x.js", len(original)), nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set("Range", "bytes=0-3")
+	w := httptest.NewRecorder()
+
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusPartialContent)
+	}
+	if enc := w.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("got Content-Encoding %q, want gzip", enc)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Fatalf("got Content-Type %q, want it to reference javascript", ct)
+	}
+	if w.Header().Get("ETag") == "" {
+		t.Fatalf("expected ETag header to be set from the original file's hash")
+	}
+	if w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("expected Last-Modified header to be set")
+	}
+
+	got := w.Body.Bytes()
+	want := compressed[0:4]
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got range body %v, want %v (first 4 bytes of the compressed sibling)", got, want)
+	}
+}
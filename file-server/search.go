@@ -0,0 +1,214 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IndexFileItem is a single entry of the search index built by
+// StartSearchIndex.
+type IndexFileItem struct {
+	Path string
+	Info os.FileInfo
+}
+
+// MarshalJSON implements json.Marshaler. os.FileInfo implementations carry
+// unexported fields and would otherwise marshal to "{}", so Info is
+// flattened into the fields search clients actually need.
+func (i IndexFileItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path    string    `json:"path"`
+		Name    string    `json:"name"`
+		Size    int64     `json:"size"`
+		ModTime time.Time `json:"mtime"`
+		IsDir   bool      `json:"is_dir"`
+	}{
+		Path:    i.Path,
+		Name:    i.Info.Name(),
+		Size:    i.Info.Size(),
+		ModTime: i.Info.ModTime(),
+		IsDir:   i.Info.IsDir(),
+	})
+}
+
+// StartSearchIndex starts a background goroutine that rebuilds the search
+// index exposed by SearchHandler every Options.SearchIndexInterval. It
+// builds the index once before returning. Calling it when
+// SearchIndexInterval is not positive is a no-op. The returned stop
+// function terminates the goroutine.
+func (s *Server) StartSearchIndex() (stop func()) {
+	if s.SearchIndexInterval <= 0 {
+		return func() {}
+	}
+	s.rebuildSearchIndex()
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(s.SearchIndexInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				s.rebuildSearchIndex()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (s *Server) rebuildSearchIndex() {
+	var items []IndexFileItem
+	seen := map[string]bool{}
+
+	s.walkSearchIndexSource(s.altSource, &items, seen)
+	s.walkSearchIndexSource(s.source, &items, seen)
+
+	s.searchMu.Lock()
+	s.searchIndex = items
+	s.searchMu.Unlock()
+}
+
+// walkSearchIndexSource walks src through its Walk method, the same
+// abstraction s.open is built on, so that Source and AltDir overrides are
+// honored the same way they are for regular requests. Entries already
+// collected from a higher-priority source (AltDir) are not duplicated.
+func (s *Server) walkSearchIndexSource(src Source, items *[]IndexFileItem, seen map[string]bool) {
+	if src == nil {
+		return
+	}
+	src.Walk("/", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if p == "/" {
+			return nil
+		}
+		if path.Base(p) == s.configFilename() {
+			return nil
+		}
+		if s.searchIgnored(path.Base(p)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+		*items = append(*items, IndexFileItem{Path: p, Info: info})
+		return nil
+	})
+}
+
+func (s *Server) searchIgnored(name string) bool {
+	for _, pattern := range s.SearchIgnore {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// searchResult is the JSON response body served by SearchHandler.
+type searchResult struct {
+	Items []IndexFileItem `json:"items"`
+	Total int             `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+}
+
+// SearchHandler returns a http.Handler that serves the index built by
+// StartSearchIndex as JSON. The "q" query parameter is matched as a
+// case-insensitive substring of the path, or as a regular expression when
+// "regex=1" is set. Results can be restricted with "type=dir" or
+// "type=file", and paginated with "page" (1-based) and "limit".
+func (s *Server) SearchHandler() http.Handler {
+	return http.HandlerFunc(s.serveSearch)
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := q.Get("q")
+
+	var re *regexp.Regexp
+	if q.Get("regex") == "1" && query != "" {
+		var err error
+		re, err = regexp.Compile(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	typ := q.Get("type")
+
+	limit := 50
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	page := 1
+	if v, err := strconv.Atoi(q.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+
+	s.searchMu.RLock()
+	index := s.searchIndex
+	s.searchMu.RUnlock()
+
+	var matched []IndexFileItem
+	for _, item := range index {
+		if typ == "dir" && !item.Info.IsDir() {
+			continue
+		}
+		if typ == "file" && item.Info.IsDir() {
+			continue
+		}
+		switch {
+		case re != nil:
+			if !re.MatchString(item.Path) {
+				continue
+			}
+		case query != "":
+			if !strings.Contains(strings.ToLower(item.Path), strings.ToLower(query)) {
+				continue
+			}
+		}
+		matched = append(matched, item)
+	}
+
+	total := len(matched)
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searchResult{
+		Items: matched[start:end],
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexFileItemMarshalJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileserver-search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a.txt")
+	if err := ioutil.WriteFile(name, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := IndexFileItem{Path: "/a.txt", Info: info}
+
+	b, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["path"] != "/a.txt" {
+		t.Errorf("got path %v, want /a.txt", got["path"])
+	}
+	if got["name"] != "a.txt" {
+		t.Errorf("got name %v, want a.txt", got["name"])
+	}
+	if got["size"].(float64) != float64(info.Size()) {
+		t.Errorf("got size %v, want %v", got["size"], info.Size())
+	}
+	if got["is_dir"] != false {
+		t.Errorf("got is_dir %v, want false", got["is_dir"])
+	}
+}
@@ -21,24 +21,51 @@ type Server struct {
 	root string
 	dir  string
 
+	source    Source
+	altSource Source
+
 	hashes map[string]string
 	mu     *sync.RWMutex
+
+	searchIndex []IndexFileItem
+	searchMu    *sync.RWMutex
+
+	overlayCache *overlayCache
 }
 
-// New initializes a new instance of Server.
+// New initializes a new instance of Server that serves files from dir on
+// the local filesystem.
 func New(root, dir string, options *Options) *Server {
+	s := NewFromFS(root, NewHTTPFileSystemSource(http.Dir(dir)), options)
+	s.dir = dir
+	return s
+}
+
+// NewFromFS initializes a new instance of Server that serves files from an
+// arbitrary Source, such as an embedded io/fs.FS or a zip archive, instead
+// of the local filesystem.
+func NewFromFS(root string, source Source, options *Options) *Server {
 	if options == nil {
 		options = &Options{}
 	}
-	return &Server{
+	s := &Server{
 		Options: *options,
 
 		root: root,
-		dir:  dir,
+
+		source: source,
 
 		hashes: map[string]string{},
 		mu:     &sync.RWMutex{},
+
+		searchMu: &sync.RWMutex{},
+
+		overlayCache: newOverlayCache(0),
 	}
+	if options.AltDir != "" {
+		s.altSource = NewHTTPFileSystemSource(http.Dir(options.AltDir))
+	}
+	return s
 }
 
 // ServeHTTP writes static files content to HTTP response.
@@ -62,6 +89,29 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if path.Base(p) == s.configFilename() {
+		s.httpError(w, r, errNotFound)
+		return
+	}
+
+	dirOverlay := s.overlayFor(path.Dir(p))
+	if to, code, ok := dirOverlay.matchRedirect(p); ok {
+		http.Redirect(w, r, to, code)
+		return
+	}
+	if dirOverlay.isHidden(path.Base(p)) {
+		s.httpErrorOverlay(w, r, errNotFound, dirOverlay)
+		return
+	}
+	if !dirOverlay.authorize(w, r) {
+		return
+	}
+
+	if script, ok := s.resolveCGI(p); ok {
+		s.serveCGI(w, r, script)
+		return
+	}
+
 	if (s.Hasher != nil && !s.NoHashQueryStrings) ||
 		(s.Hasher != nil && s.NoHashQueryStrings && len(r.URL.RawQuery) == 0) {
 		cPath := s.canonicalPath(p)
@@ -88,17 +138,24 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	f, err := s.open(p)
 	if err != nil {
-		s.httpError(w, r, err)
+		s.httpErrorOverlay(w, r, err, dirOverlay)
 		return
 	}
 	defer f.Close()
 
 	d, err := f.Stat()
 	if err != nil {
-		s.httpError(w, r, err)
+		s.httpErrorOverlay(w, r, err, dirOverlay)
 		return
 	}
 
+	if dirOverlay.CacheControl != "" {
+		w.Header().Set("Cache-Control", dirOverlay.CacheControl)
+	}
+	if dirOverlay.ContentSecurityPolicy != "" {
+		w.Header().Set("Content-Security-Policy", dirOverlay.ContentSecurityPolicy)
+	}
+
 	if s.RedirectTrailingSlash {
 		url := r.URL.Path
 		if d.IsDir() {
@@ -128,11 +185,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if d.IsDir() {
-		s.httpError(w, r, errNotFound)
+		if s.Browse {
+			s.serveBrowse(w, r, p, f)
+			return
+		}
+		s.httpErrorOverlay(w, r, errNotFound, dirOverlay)
 		return
 	}
 
-	http.ServeContent(w, r, d.Name(), d.ModTime(), f)
+	s.serveContent(w, r, p, d, f)
 }
 
 // HashedPath returns a URL path with hash injected into the filename.
@@ -237,22 +298,24 @@ func (s *Server) hashFromFilename(p string) (h string, cont bool, err error) {
 			}
 		}
 	} else {
+		dir, base := path.Split(p)
+		fnBase := strings.TrimSuffix(base, ext)
 		pattern := ""
 		if ext != "" {
-			pattern = fn + ".*" + ext
+			pattern = fnBase + ".*" + ext
 		} else {
-			pattern = p + ".*"
+			pattern = base + ".*"
 		}
 
-		if s.AltDir != "" {
-			matches, err = filepath.Glob(filepath.Join(s.AltDir, pattern))
+		if s.altSource != nil {
+			matches, err = sourceGlob(s.altSource, dir, pattern)
 			if err != nil {
 				cont = true
 				return
 			}
 		}
 		var m []string
-		m, err = filepath.Glob(filepath.Join(s.dir, pattern))
+		m, err = sourceGlob(s.source, dir, pattern)
 		if err != nil {
 			cont = true
 			return
@@ -315,12 +378,12 @@ func (s Server) canonicalPath(p string) string {
 }
 
 func (s Server) open(p string) (f http.File, err error) {
-	if s.AltDir == "" {
-		return open(s.dir, p, s.Filesystem)
+	if s.altSource == nil {
+		return s.source.Open(p)
 	}
-	f, err = open(s.AltDir, p, s.Filesystem)
+	f, err = s.altSource.Open(p)
 	if os.IsNotExist(err) {
-		f, err = open(s.dir, p, s.Filesystem)
+		f, err = s.source.Open(p)
 	}
 	return
 }
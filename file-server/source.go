@@ -0,0 +1,128 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"archive/zip"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Source abstracts the backing storage that a Server reads files from, so
+// that it can serve content from something other than the local
+// filesystem, such as an embedded io/fs.FS or a zip archive.
+type Source interface {
+	Open(name string) (http.File, error)
+	Stat(name string) (os.FileInfo, error)
+	Walk(root string, fn filepath.WalkFunc) error
+}
+
+// baseSource implements Stat and Walk generically in terms of Open, so
+// that concrete Source adapters only need to provide Open.
+type baseSource struct {
+	openFunc func(name string) (http.File, error)
+}
+
+func (b baseSource) Open(name string) (http.File, error) {
+	return b.openFunc(name)
+}
+
+func (b baseSource) Stat(name string) (os.FileInfo, error) {
+	f, err := b.openFunc(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+func (b baseSource) Walk(root string, fn filepath.WalkFunc) error {
+	return walkSource(b, root, fn)
+}
+
+// walkSource walks the file tree rooted at root, reachable through s,
+// calling fn for each file and directory, in the manner of filepath.Walk.
+func walkSource(s Source, root string, fn filepath.WalkFunc) error {
+	f, err := s.Open(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	d, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fn(root, nil, err)
+	}
+	if err := fn(root, d, nil); err != nil {
+		f.Close()
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if !d.IsDir() {
+		f.Close()
+		return nil
+	}
+	entries, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := walkSource(s, path.Join(root, e.Name()), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewHTTPFileSystemSource adapts an http.FileSystem as a Source.
+func NewHTTPFileSystemSource(hfs http.FileSystem) Source {
+	return baseSource{openFunc: hfs.Open}
+}
+
+// NewIOFSSource adapts an io/fs.FS, such as an embed.FS, as a Source.
+func NewIOFSSource(fsys fs.FS) Source {
+	return NewHTTPFileSystemSource(http.FS(fsys))
+}
+
+// NewZipSource adapts a zip.Reader as a Source, allowing a Server to serve
+// a static bundle directly from a .zip archive without unpacking it.
+func NewZipSource(zr *zip.Reader) Source {
+	return NewIOFSSource(zr)
+}
+
+// sourceGlob lists the entries of dir through s and returns the paths,
+// joined with dir, of those whose name matches the shell pattern pattern.
+func sourceGlob(s Source, dir, pattern string) ([]string, error) {
+	f, err := s.Open(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, e := range entries {
+		ok, err := path.Match(pattern, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, path.Join(dir, e.Name()))
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016, Janoš Guljaš <janos@resenje.org>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fileServer
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestServeHTTPFromIOFSSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("hello from fs")},
+	}
+
+	s := NewFromFS("", NewIOFSSource(fsys), nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "hello from fs" {
+		t.Fatalf("got body %q, want %q", got, "hello from fs")
+	}
+}
+
+func TestServeHTTPFromZipSource(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("hello from zip")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewFromFS("", NewZipSource(zr), nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "hello from zip" {
+		t.Fatalf("got body %q, want %q", got, "hello from zip")
+	}
+}
+
+func TestHashingThroughSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("hello from fs")},
+	}
+
+	s := NewFromFS("", NewIOFSSource(fsys), &Options{Hasher: fakeHasher{}})
+
+	if _, _, err := s.hash("/a.txt"); err != nil {
+		t.Fatalf("hash via Source failed: %v", err)
+	}
+}